@@ -0,0 +1,144 @@
+package scheduler
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
+	"github.com/fanruiheng/kubernetes-mesos/pkg/scheduler/hostport"
+	"github.com/fanruiheng/kubernetes-mesos/pkg/scheduler/offers"
+	"github.com/fanruiheng/kubernetes-mesos/pkg/scheduler/resource"
+	log "github.com/golang/glog"
+	"github.com/mesos/mesos-go/mesos"
+)
+
+// SlaveIndex gives scheduling algorithms and predicates visibility into what
+// else is already scheduled on a slave -- visibility that a single
+// task/offer pair doesn't have on its own.
+type SlaveIndex interface {
+	Tasks(slaveId string) []*PodTask
+}
+
+// SchedulerAlgorithm picks a PerishableOffer out of r that's suitable for
+// task, or returns an error if none is found. Implementations are free to
+// walk r in whatever order suits their strategy (first-fit, bin-packing,
+// spread, role-aware, ...).
+type SchedulerAlgorithm func(r offers.Registry, slaves SlaveIndex, task *PodTask) (offers.PerishableOffer, error)
+
+// Predicate reports whether offer is viable for task. slaves may be nil when
+// a predicate is evaluated outside the context of a SchedulerAlgorithm (e.g.
+// PodTask.AcceptOffer), in which case predicates that need it should treat
+// the check as satisfied.
+type Predicate func(task *PodTask, offer *mesos.Offer, slaves SlaveIndex) bool
+
+// DefaultPredicates are the checks every built-in SchedulerAlgorithm and
+// PodTask.AcceptOffer run against a candidate offer.
+var DefaultPredicates = []Predicate{
+	PodFitsNodeSelectorPredicate,
+	PodFitsResourcesPredicate,
+	PodFitsHostPortsPredicate,
+}
+
+// offerLabels builds a label set out of the offer's text-valued attributes,
+// so that it can be matched against a pod's NodeSelector.
+func offerLabels(offer *mesos.Offer) labels.Set {
+	set := labels.Set{}
+	for _, attr := range offer.GetAttributes() {
+		if attr.GetType() == mesos.Value_TEXT {
+			set[attr.GetName()] = attr.GetText().GetValue()
+		}
+	}
+	return set
+}
+
+// PodFitsNodeSelectorPredicate rejects offers from a host the pod didn't
+// request (when pinned via Spec.Host) or whose attributes don't satisfy the
+// pod's NodeSelector.
+func PodFitsNodeSelectorPredicate(task *PodTask, offer *mesos.Offer, _ SlaveIndex) bool {
+	if host := task.Pod.Spec.Host; host != "" && host != offer.GetHostname() {
+		log.V(2).Infof("Skipping offer, required host %q does not match offered host %q", host, offer.GetHostname())
+		return false
+	}
+	if selector := labels.SelectorFromSet(task.Pod.Spec.NodeSelector); !selector.Matches(offerLabels(offer)) {
+		log.V(2).Infof("Skipping offer, nodeSelector %v does not match offered attributes", task.Pod.Spec.NodeSelector)
+		return false
+	}
+	return true
+}
+
+// PodFitsResourcesPredicate rejects offers that don't have enough cpu/mem,
+// drawn only from resources tagged with task's primary role, to satisfy the
+// same cpu/mem quantities FillTaskInfo actually reserves in the TaskInfo it
+// builds (PodCPULimit/PodMemLimit) -- not the (possibly lower) request.
+// FillTaskInfo tags its entire reservation with a single role
+// (primaryRole(task.Roles)), so the predicate must sum only that role too;
+// otherwise a mix of roles could satisfy this check while leaving the
+// single-role TaskInfo short of what the offer actually has for that role.
+func PodFitsResourcesPredicate(task *PodTask, offer *mesos.Offer, _ SlaveIndex) bool {
+	// FillTaskInfo sizes the task after ApplyDefaults has floored each unset
+	// limit at its request; do the same here (it's idempotent) so both agree.
+	resource.ApplyDefaults(task.Pod, task.Resources)
+	cpuLimit := float64(resource.PodCPULimit(task.Pod, task.Resources))
+	memLimit := float64(resource.PodMemLimit(task.Pod, task.Resources))
+
+	role := primaryRole(task.Roles)
+	var cpus, mem float64
+	for _, res := range offer.Resources {
+		if resourceRole(res) != role {
+			continue
+		}
+		switch res.GetName() {
+		case "cpus":
+			cpus += res.GetScalar().GetValue()
+		case "mem":
+			mem += res.GetScalar().GetValue()
+		}
+	}
+
+	if cpus < cpuLimit || mem < memLimit {
+		log.V(2).Infof("Not enough resources: cpus: %f mem: %f", cpus, mem)
+		return false
+	}
+	return true
+}
+
+// PodFitsHostPortsPredicate rejects offers that can't satisfy the pod's host
+// port requirements, drawing only from ports resources tagged with task's
+// primary role. FillTaskInfo tags the entire assigned ports range with a
+// single primaryRole(task.Roles), so the predicate must pick ports from that
+// same role too; otherwise a port offered only under a different role in
+// task.Roles (e.g. "*") could be chosen and then emitted under a role it was
+// never actually offered with.
+func PodFitsHostPortsPredicate(task *PodTask, offer *mesos.Offer, _ SlaveIndex) bool {
+	mapper := hostport.MapperFor(task.Pod)
+	mappings, err := mapper.Generate(task.Pod, filterOfferRoles(offer, []string{primaryRole(task.Roles)}))
+	if err != nil {
+		log.V(2).Infof("Could not schedule pod %s: %v", task.Pod.Name, err)
+		return false
+	}
+	task.PortMappings = mappings
+	return true
+}
+
+// FCFSScheduleFunc is the simplest possible SchedulerAlgorithm: it walks the
+// offer registry in whatever order it's handed to us and takes the first
+// live offer that fits task.
+func FCFSScheduleFunc(r offers.Registry, slaves SlaveIndex, task *PodTask) (offers.PerishableOffer, error) {
+	var chosen offers.PerishableOffer
+	err := r.Walk(func(p offers.PerishableOffer) (bool, error) {
+		if !task.AcceptOffer(p.Details()) {
+			return false, nil
+		}
+		if !p.Acquire() {
+			return false, nil
+		}
+		chosen = p
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if chosen == nil {
+		return nil, fmt.Errorf("no offer satisfies task %s", task.ID)
+	}
+	return chosen, nil
+}
@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"testing"
+
+	"code.google.com/p/goprotobuf/proto"
+	"github.com/mesos/mesos-go/mesos"
+)
+
+func portsResource(role string, begin, end uint64) *mesos.Resource {
+	res := &mesos.Resource{
+		Name:   proto.String("ports"),
+		Type:   mesos.Value_RANGES.Enum(),
+		Ranges: &mesos.Value_Ranges{Range: []*mesos.Value_Range{valueRange(begin, end)}},
+	}
+	if role != defaultRole {
+		res.Role = proto.String(role)
+	}
+	return res
+}
+
+func TestPodFitsHostPortsPredicateRejectsNonContiguousRoleMix(t *testing.T) {
+	// task's primary role ("public") doesn't have the requested port on its
+	// own -- it's only covered under "*". FillTaskInfo tags the assigned
+	// port range with the single primary role, so a port picked from "*"
+	// must not be accepted here.
+	task := &PodTask{
+		Pod:   podWithHostPort(0, 8080),
+		Roles: []string{"public", "*"},
+	}
+	offer := &mesos.Offer{
+		Resources: []*mesos.Resource{
+			portsResource("*", 8000, 9000),
+		},
+	}
+	if PodFitsHostPortsPredicate(task, offer, nil) {
+		t.Error("expected the predicate to reject a port only offered under a non-primary role")
+	}
+}
+
+func TestPodFitsHostPortsPredicateSingleRole(t *testing.T) {
+	task := &PodTask{
+		Pod:   podWithHostPort(0, 8080),
+		Roles: []string{"public"},
+	}
+	offer := &mesos.Offer{
+		Resources: []*mesos.Resource{
+			portsResource("public", 8000, 9000),
+		},
+	}
+	if !PodFitsHostPortsPredicate(task, offer, nil) {
+		t.Error("expected the predicate to fit when the primary role itself offers the port")
+	}
+}
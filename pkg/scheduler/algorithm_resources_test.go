@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"testing"
+
+	"code.google.com/p/goprotobuf/proto"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
+	schedresource "github.com/fanruiheng/kubernetes-mesos/pkg/scheduler/resource"
+	"github.com/mesos/mesos-go/mesos"
+)
+
+func scalarResource(name, role string, value float64) *mesos.Resource {
+	res := mesos.ScalarResource(name, value)
+	if role != defaultRole {
+		res.Role = proto.String(role)
+	}
+	return res
+}
+
+func podRequestingCPUMem(cpu, mem float64) *api.Pod {
+	return &api.Pod{
+		Spec: api.PodSpec{
+			Containers: []api.Container{
+				{
+					Resources: api.ResourceRequirements{
+						Limits: api.ResourceList{
+							api.ResourceCPU:    *resource.NewMilliQuantity(int64(cpu*1000), resource.DecimalSI),
+							api.ResourceMemory: *resource.NewQuantity(int64(mem), resource.BinarySI),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestPodFitsResourcesPredicateSingleRole(t *testing.T) {
+	task := &PodTask{
+		Pod:       podRequestingCPUMem(1, 1024*1024),
+		Resources: schedresource.DefaultConfig,
+		Roles:     []string{"public"},
+	}
+	offer := &mesos.Offer{
+		Resources: []*mesos.Resource{
+			scalarResource("cpus", "public", 2),
+			scalarResource("mem", "public", 2*1024*1024),
+		},
+	}
+	if !PodFitsResourcesPredicate(task, offer, nil) {
+		t.Error("expected the predicate to fit when the primary role alone has enough resources")
+	}
+}
+
+func TestPodFitsResourcesPredicateRejectsNonContiguousRoleMix(t *testing.T) {
+	// The task's primary role ("public") only has enough on its own if it's
+	// topped up from the "*" pool -- but FillTaskInfo tags its entire
+	// reservation with a single role, so a mix like this must not pass.
+	task := &PodTask{
+		Pod:       podRequestingCPUMem(2, 2*1024*1024),
+		Resources: schedresource.DefaultConfig,
+		Roles:     []string{"public", "*"},
+	}
+	offer := &mesos.Offer{
+		Resources: []*mesos.Resource{
+			scalarResource("cpus", "public", 1),
+			scalarResource("mem", "public", 1024*1024),
+			scalarResource("cpus", "*", 4),
+			scalarResource("mem", "*", 4*1024*1024),
+		},
+	}
+	if PodFitsResourcesPredicate(task, offer, nil) {
+		t.Error("expected the predicate to reject an offer that only fits by mixing roles")
+	}
+}
+
+func TestPodFitsResourcesPredicateInsufficientPrimaryRole(t *testing.T) {
+	task := &PodTask{
+		Pod:       podRequestingCPUMem(2, 2*1024*1024),
+		Resources: schedresource.DefaultConfig,
+		Roles:     []string{"public"},
+	}
+	offer := &mesos.Offer{
+		Resources: []*mesos.Resource{
+			scalarResource("cpus", "public", 1),
+			scalarResource("mem", "public", 1024*1024),
+		},
+	}
+	if PodFitsResourcesPredicate(task, offer, nil) {
+		t.Error("expected the predicate to reject an offer whose primary-role resources are insufficient")
+	}
+}
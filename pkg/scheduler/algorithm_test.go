@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"testing"
+
+	"code.google.com/p/goprotobuf/proto"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/mesos/mesos-go/mesos"
+)
+
+func textAttribute(name, value string) *mesos.Attribute {
+	return &mesos.Attribute{
+		Name: proto.String(name),
+		Type: mesos.Value_TEXT.Enum(),
+		Text: &mesos.Value_Text{Value: proto.String(value)},
+	}
+}
+
+func offerWithAttributes(hostname string, attrs ...*mesos.Attribute) *mesos.Offer {
+	return &mesos.Offer{
+		Hostname:   proto.String(hostname),
+		Attributes: attrs,
+	}
+}
+
+func taskWithPod(pod *api.Pod) *PodTask {
+	return &PodTask{Pod: pod}
+}
+
+func TestPodFitsNodeSelectorPredicateNoConstraints(t *testing.T) {
+	task := taskWithPod(&api.Pod{})
+	offer := offerWithAttributes("slave1")
+	if !PodFitsNodeSelectorPredicate(task, offer, nil) {
+		t.Error("expected offer with no constraints to fit a pod with no constraints")
+	}
+}
+
+func TestPodFitsNodeSelectorPredicateHostMismatch(t *testing.T) {
+	pod := &api.Pod{Spec: api.PodSpec{Host: "slave2"}}
+	task := taskWithPod(pod)
+	offer := offerWithAttributes("slave1")
+	if PodFitsNodeSelectorPredicate(task, offer, nil) {
+		t.Error("expected offer from a different host than pinned to be rejected")
+	}
+}
+
+func TestPodFitsNodeSelectorPredicateHostMatch(t *testing.T) {
+	pod := &api.Pod{Spec: api.PodSpec{Host: "slave1"}}
+	task := taskWithPod(pod)
+	offer := offerWithAttributes("slave1")
+	if !PodFitsNodeSelectorPredicate(task, offer, nil) {
+		t.Error("expected offer from the pinned host to fit")
+	}
+}
+
+func TestPodFitsNodeSelectorPredicateSelectorMatch(t *testing.T) {
+	pod := &api.Pod{Spec: api.PodSpec{NodeSelector: map[string]string{"rack": "a"}}}
+	task := taskWithPod(pod)
+	offer := offerWithAttributes("slave1", textAttribute("rack", "a"))
+	if !PodFitsNodeSelectorPredicate(task, offer, nil) {
+		t.Error("expected offer with a matching attribute to fit")
+	}
+}
+
+func TestPodFitsNodeSelectorPredicateSelectorMismatch(t *testing.T) {
+	pod := &api.Pod{Spec: api.PodSpec{NodeSelector: map[string]string{"rack": "a"}}}
+	task := taskWithPod(pod)
+	offer := offerWithAttributes("slave1", textAttribute("rack", "b"))
+	if PodFitsNodeSelectorPredicate(task, offer, nil) {
+		t.Error("expected offer with a mismatched attribute to be rejected")
+	}
+}
+
+func TestPodFitsNodeSelectorPredicateSelectorMissingAttribute(t *testing.T) {
+	pod := &api.Pod{Spec: api.PodSpec{NodeSelector: map[string]string{"rack": "a"}}}
+	task := taskWithPod(pod)
+	offer := offerWithAttributes("slave1")
+	if PodFitsNodeSelectorPredicate(task, offer, nil) {
+		t.Error("expected offer missing the required attribute to be rejected")
+	}
+}
@@ -0,0 +1,110 @@
+package scheduler
+
+import (
+	"testing"
+
+	"code.google.com/p/goprotobuf/proto"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/fanruiheng/kubernetes-mesos/pkg/scheduler/offers"
+	"github.com/mesos/mesos-go/mesos"
+)
+
+func podWithHostPort(containerPort, hostPort int) *api.Pod {
+	return &api.Pod{
+		Spec: api.PodSpec{
+			Containers: []api.Container{
+				{Ports: []api.Port{{ContainerPort: containerPort, HostPort: hostPort}}},
+			},
+		},
+	}
+}
+
+func offerWithPortRange(hostname string, begin, end uint64) *mesos.Offer {
+	return &mesos.Offer{
+		Hostname: proto.String(hostname),
+		Resources: []*mesos.Resource{
+			{
+				Name:   proto.String("ports"),
+				Type:   mesos.Value_RANGES.Enum(),
+				Ranges: &mesos.Value_Ranges{Range: []*mesos.Value_Range{valueRange(begin, end)}},
+			},
+		},
+	}
+}
+
+// fakeOffer is a minimal offers.PerishableOffer for exercising
+// FCFSScheduleFunc without a real offers.Registry.
+type fakeOffer struct {
+	details  *mesos.Offer
+	acquired bool
+}
+
+func (f *fakeOffer) Details() *mesos.Offer { return f.details }
+func (f *fakeOffer) Acquire() bool {
+	if f.acquired {
+		return false
+	}
+	f.acquired = true
+	return true
+}
+func (f *fakeOffer) Release() { f.acquired = false }
+
+// fakeRegistry walks a fixed, in-order slice of offers.
+type fakeRegistry struct {
+	offers []offers.PerishableOffer
+}
+
+func (r *fakeRegistry) Add(offer *mesos.Offer) {}
+func (r *fakeRegistry) Delete(offerId string)  {}
+func (r *fakeRegistry) Walk(w offers.Walker) error {
+	for _, o := range r.offers {
+		done, err := w(o)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+	return nil
+}
+
+func TestFCFSScheduleFuncSkipsUnfitOffer(t *testing.T) {
+	task := &PodTask{Pod: podWithHostPort(0, 8080), Roles: FrameworkRoles}
+	badOffer := &fakeOffer{details: offerWithAttributes("slave1")}
+	goodOffer := &fakeOffer{details: offerWithPortRange("slave2", 8000, 9000)}
+	reg := &fakeRegistry{offers: []offers.PerishableOffer{badOffer, goodOffer}}
+
+	chosen, err := FCFSScheduleFunc(reg, nil, task)
+	if err != nil {
+		t.Fatalf("FCFSScheduleFunc returned error: %v", err)
+	}
+	if chosen != goodOffer {
+		t.Errorf("expected FCFSScheduleFunc to pick the offer that actually has the port, got %v", chosen)
+	}
+}
+
+func TestFCFSScheduleFuncNoFitReturnsError(t *testing.T) {
+	task := &PodTask{Pod: podWithHostPort(0, 8080), Roles: FrameworkRoles}
+	badOffer := &fakeOffer{details: offerWithAttributes("slave1")}
+	reg := &fakeRegistry{offers: []offers.PerishableOffer{badOffer}}
+
+	if _, err := FCFSScheduleFunc(reg, nil, task); err == nil {
+		t.Error("expected an error when no offer fits the task")
+	}
+}
+
+func TestFCFSScheduleFuncSkipsAlreadyAcquiredOffer(t *testing.T) {
+	task := &PodTask{Pod: podWithHostPort(0, 8080), Roles: FrameworkRoles}
+	taken := &fakeOffer{details: offerWithPortRange("slave1", 8000, 9000), acquired: true}
+	free := &fakeOffer{details: offerWithPortRange("slave2", 8000, 9000)}
+	reg := &fakeRegistry{offers: []offers.PerishableOffer{taken, free}}
+
+	chosen, err := FCFSScheduleFunc(reg, nil, task)
+	if err != nil {
+		t.Fatalf("FCFSScheduleFunc returned error: %v", err)
+	}
+	if chosen != free {
+		t.Errorf("expected FCFSScheduleFunc to skip the already-acquired offer, got %v", chosen)
+	}
+}
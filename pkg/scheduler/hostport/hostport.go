@@ -0,0 +1,171 @@
+// Package hostport decides which host port each of a pod's container ports
+// should bind to on the offered slave.
+package hostport
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/mesos/mesos-go/mesos"
+)
+
+// PortMappingAnnotationKey selects the Mapper used for a pod; the value is
+// one of PortMappingWildcard or PortMappingFixed. Pods without the
+// annotation get PortMappingFixed, matching historical behavior.
+const PortMappingAnnotationKey = "k8s.mesosphere.io/portMapping"
+
+const (
+	PortMappingWildcard = "wildcard"
+	PortMappingFixed    = "fixed"
+)
+
+// Mapping records that a container's port was bound to a particular host
+// port, so that it can be serialized for the executor to act on.
+type Mapping struct {
+	ContainerIdx  int    `json:"containerIdx"`
+	PortIdx       int    `json:"portIdx"`
+	ContainerPort uint64 `json:"containerPort"`
+	HostPort      uint64 `json:"hostPort"`
+}
+
+// Mapper decides which host port each of pod's container ports would use,
+// given the ports available on offer. Generate is a pure function: a
+// scheduling algorithm may call it against several candidate offers before
+// one is actually accepted, so it must never mutate pod. Call Apply with
+// the Mapping from the offer that was actually bound to commit the
+// assignment into pod's container ports.
+type Mapper interface {
+	Generate(pod *api.Pod, offer *mesos.Offer) ([]Mapping, error)
+}
+
+// Apply writes mappings' host ports back into pod's container ports. Call
+// it only once the offer mappings was generated from has actually been
+// accepted for the task, so that a rejected or raced offer never leaves a
+// stale assignment behind.
+func Apply(pod *api.Pod, mappings []Mapping) {
+	for _, m := range mappings {
+		pod.Spec.Containers[m.ContainerIdx].Ports[m.PortIdx].HostPort = int(m.HostPort)
+	}
+}
+
+// MapperFor returns the Mapper selected by pod's PortMappingAnnotationKey
+// annotation, defaulting to FixedMapper.
+func MapperFor(pod *api.Pod) Mapper {
+	switch pod.Annotations[PortMappingAnnotationKey] {
+	case PortMappingWildcard:
+		return WildcardMapper{}
+	default:
+		return FixedMapper{}
+	}
+}
+
+// FixedMapper only binds container ports that already declare an explicit
+// HostPort, and only if that port is actually free on offer; ports left at
+// HostPort 0 are not mapped. This is the historical behavior.
+type FixedMapper struct{}
+
+func (FixedMapper) Generate(pod *api.Pod, offer *mesos.Offer) ([]Mapping, error) {
+	free := portSet(offer)
+	var mappings []Mapping
+	for ci, c := range pod.Spec.Containers {
+		for pi, p := range c.Ports {
+			if p.HostPort == 0 {
+				continue
+			}
+			hostPort := uint64(p.HostPort)
+			if _, ok := free[hostPort]; !ok {
+				return nil, fmt.Errorf("host port %d for container %d port %d is not available on this offer", hostPort, ci, pi)
+			}
+			mappings = append(mappings, Mapping{
+				ContainerIdx:  ci,
+				PortIdx:       pi,
+				ContainerPort: uint64(p.ContainerPort),
+				HostPort:      hostPort,
+			})
+		}
+	}
+	return mappings, nil
+}
+
+// WildcardMapper assigns a free port from offer to every container port left
+// at HostPort 0; ports with an explicit HostPort are honored as in
+// FixedMapper, and likewise rejected if the offer doesn't actually have them
+// free.
+type WildcardMapper struct{}
+
+func (WildcardMapper) Generate(pod *api.Pod, offer *mesos.Offer) ([]Mapping, error) {
+	free := availablePorts(offer)
+	taken := make(map[uint64]struct{})
+	var mappings []Mapping
+
+	for ci, c := range pod.Spec.Containers {
+		for pi, p := range c.Ports {
+			hostPort := uint64(p.HostPort)
+			if hostPort != 0 {
+				if _, ok := taken[hostPort]; ok {
+					return nil, fmt.Errorf("host port %d for container %d port %d is not available on this offer", hostPort, ci, pi)
+				}
+				found := false
+				for _, port := range free {
+					if port == hostPort {
+						found = true
+						break
+					}
+				}
+				if !found {
+					return nil, fmt.Errorf("host port %d for container %d port %d is not available on this offer", hostPort, ci, pi)
+				}
+			} else {
+				var ok bool
+				hostPort, ok = nextFreePort(free, taken)
+				if !ok {
+					return nil, fmt.Errorf("no free port available on offer for container %d port %d", ci, pi)
+				}
+			}
+			taken[hostPort] = struct{}{}
+			mappings = append(mappings, Mapping{
+				ContainerIdx:  ci,
+				PortIdx:       pi,
+				ContainerPort: uint64(p.ContainerPort),
+				HostPort:      hostPort,
+			})
+		}
+	}
+	return mappings, nil
+}
+
+func nextFreePort(free []uint64, taken map[uint64]struct{}) (uint64, bool) {
+	for _, port := range free {
+		if _, ok := taken[port]; !ok {
+			return port, true
+		}
+	}
+	return 0, false
+}
+
+// portSet expands offer's "ports" resources into the set of individual
+// ports it covers.
+func portSet(offer *mesos.Offer) map[uint64]struct{} {
+	set := make(map[uint64]struct{})
+	for _, port := range availablePorts(offer) {
+		set[port] = struct{}{}
+	}
+	return set
+}
+
+// availablePorts expands offer's "ports" resources into the list of
+// individual ports it covers.
+func availablePorts(offer *mesos.Offer) []uint64 {
+	var ports []uint64
+	for _, res := range offer.Resources {
+		if res.GetName() != "ports" {
+			continue
+		}
+		for _, r := range res.GetRanges().Range {
+			for port := r.GetBegin(); port <= r.GetEnd(); port++ {
+				ports = append(ports, port)
+			}
+		}
+	}
+	return ports
+}
@@ -0,0 +1,138 @@
+package hostport
+
+import (
+	"testing"
+
+	"code.google.com/p/goprotobuf/proto"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/mesos/mesos-go/mesos"
+)
+
+func podWithPorts(hostPorts ...int) *api.Pod {
+	var ports []api.Port
+	for _, hp := range hostPorts {
+		ports = append(ports, api.Port{ContainerPort: 80, HostPort: hp})
+	}
+	return &api.Pod{
+		Spec: api.PodSpec{
+			Containers: []api.Container{{Ports: ports}},
+		},
+	}
+}
+
+func offerWithPorts(begin, end uint64) *mesos.Offer {
+	return &mesos.Offer{
+		Resources: []*mesos.Resource{
+			{
+				Name:   proto.String("ports"),
+				Type:   mesos.Value_RANGES.Enum(),
+				Ranges: &mesos.Value_Ranges{Range: []*mesos.Value_Range{{Begin: proto.Uint64(begin), End: proto.Uint64(end)}}},
+			},
+		},
+	}
+}
+
+func TestFixedMapperAcceptsPortInOffer(t *testing.T) {
+	pod := podWithPorts(8080)
+	mappings, err := FixedMapper{}.Generate(pod, offerWithPorts(8000, 9000))
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+	if len(mappings) != 1 || mappings[0].HostPort != 8080 {
+		t.Errorf("mappings = %v, want a single mapping to host port 8080", mappings)
+	}
+}
+
+func TestFixedMapperRejectsPortNotInOffer(t *testing.T) {
+	pod := podWithPorts(8080)
+	if _, err := (FixedMapper{}).Generate(pod, offerWithPorts(9000, 9100)); err == nil {
+		t.Error("expected an error for a host port the offer doesn't actually have")
+	}
+}
+
+func TestFixedMapperIgnoresZeroHostPort(t *testing.T) {
+	pod := podWithPorts(0)
+	mappings, err := FixedMapper{}.Generate(pod, offerWithPorts(8000, 9000))
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+	if len(mappings) != 0 {
+		t.Errorf("mappings = %v, want none for a container port left at HostPort 0", mappings)
+	}
+}
+
+func TestWildcardMapperAssignsFreePort(t *testing.T) {
+	pod := podWithPorts(0)
+	mappings, err := WildcardMapper{}.Generate(pod, offerWithPorts(8000, 8000))
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+	if len(mappings) != 1 || mappings[0].HostPort != 8000 {
+		t.Errorf("mappings = %v, want a single mapping to host port 8000", mappings)
+	}
+}
+
+func TestWildcardMapperDoesNotMutatePod(t *testing.T) {
+	// Generate must be pure: a scheduling algorithm may call it against
+	// several candidate offers before one is actually accepted, so it must
+	// never write the chosen port back into pod.Spec itself.
+	pod := podWithPorts(0)
+	if _, err := (WildcardMapper{}).Generate(pod, offerWithPorts(8000, 8000)); err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+	if got := pod.Spec.Containers[0].Ports[0].HostPort; got != 0 {
+		t.Errorf("pod.Spec.Containers[0].Ports[0].HostPort = %d, want 0 (unmutated)", got)
+	}
+}
+
+func TestWildcardMapperRejectsStaleHostPortAcrossOffers(t *testing.T) {
+	// Simulates FCFSScheduleFunc walking two offers for the same task: the
+	// first offer's generated mapping must not be trusted as an "explicit"
+	// request when re-evaluating against the second offer.
+	pod := podWithPorts(0)
+
+	first, err := (WildcardMapper{}).Generate(pod, offerWithPorts(8000, 8000))
+	if err != nil {
+		t.Fatalf("Generate against first offer returned error: %v", err)
+	}
+	if len(first) != 1 {
+		t.Fatalf("expected one mapping from the first offer, got %v", first)
+	}
+
+	// Even though the pod spec itself was never mutated, pretend a caller
+	// mistakenly re-applied the stale mapping before trying a second offer
+	// that doesn't actually have that port.
+	Apply(pod, first)
+
+	if _, err := (WildcardMapper{}).Generate(pod, offerWithPorts(9000, 9000)); err == nil {
+		t.Error("expected an error: the port assigned from the first offer isn't available on the second")
+	}
+}
+
+func TestApplyWritesMappingsIntoPod(t *testing.T) {
+	pod := podWithPorts(0, 0)
+	mappings := []Mapping{
+		{ContainerIdx: 0, PortIdx: 0, ContainerPort: 80, HostPort: 8000},
+		{ContainerIdx: 0, PortIdx: 1, ContainerPort: 80, HostPort: 8001},
+	}
+	Apply(pod, mappings)
+
+	if got := pod.Spec.Containers[0].Ports[0].HostPort; got != 8000 {
+		t.Errorf("Ports[0].HostPort = %d, want 8000", got)
+	}
+	if got := pod.Spec.Containers[0].Ports[1].HostPort; got != 8001 {
+		t.Errorf("Ports[1].HostPort = %d, want 8001", got)
+	}
+}
+
+func TestMapperForSelectsByAnnotation(t *testing.T) {
+	pod := &api.Pod{}
+	if _, ok := MapperFor(pod).(FixedMapper); !ok {
+		t.Error("expected FixedMapper for a pod with no portMapping annotation")
+	}
+
+	pod.Annotations = map[string]string{PortMappingAnnotationKey: PortMappingWildcard}
+	if _, ok := MapperFor(pod).(WildcardMapper); !ok {
+		t.Error("expected WildcardMapper for a pod annotated with wildcard")
+	}
+}
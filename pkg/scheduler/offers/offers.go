@@ -0,0 +1,111 @@
+// Package offers tracks the Mesos resource offers a scheduler currently
+// holds, and lets scheduling algorithms walk them without caring how they're
+// stored or expired.
+package offers
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mesos/mesos-go/mesos"
+)
+
+// PerishableOffer wraps a Mesos offer that may be withdrawn or expire out
+// from under the scheduler at any moment. Acquire claims the offer for
+// exclusive use by a single task; it returns false if the offer has already
+// been claimed or has perished. Release gives it back.
+type PerishableOffer interface {
+	Details() *mesos.Offer
+	Acquire() bool
+	Release()
+}
+
+// Walker is called once per offer during a Registry.Walk. Returning true
+// stops the walk early (e.g. because a suitable offer was found);
+// returning a non-nil error aborts the walk and is propagated to the
+// caller of Walk.
+type Walker func(PerishableOffer) (bool, error)
+
+// Registry is the set of offers a scheduler currently holds.
+type Registry interface {
+	Add(offer *mesos.Offer)
+	Delete(offerId string)
+	Walk(w Walker) error
+}
+
+type perishableOffer struct {
+	details  *mesos.Offer
+	mutex    sync.Mutex
+	acquired bool
+	expired  bool
+}
+
+func (p *perishableOffer) Details() *mesos.Offer {
+	return p.details
+}
+
+func (p *perishableOffer) Acquire() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.acquired || p.expired {
+		return false
+	}
+	p.acquired = true
+	return true
+}
+
+func (p *perishableOffer) Release() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.acquired = false
+}
+
+// registry is a simple in-memory Registry keyed by offer id.
+type registry struct {
+	mutex  sync.Mutex
+	offers map[string]*perishableOffer
+}
+
+// NewRegistry returns a Registry backed by an in-memory map of live offers.
+func NewRegistry() Registry {
+	return &registry{
+		offers: make(map[string]*perishableOffer),
+	}
+}
+
+func (r *registry) Add(offer *mesos.Offer) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.offers[offer.Id.GetValue()] = &perishableOffer{details: offer}
+}
+
+func (r *registry) Delete(offerId string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if p, ok := r.offers[offerId]; ok {
+		p.mutex.Lock()
+		p.expired = true
+		p.mutex.Unlock()
+		delete(r.offers, offerId)
+	}
+}
+
+func (r *registry) Walk(w Walker) error {
+	r.mutex.Lock()
+	live := make([]*perishableOffer, 0, len(r.offers))
+	for _, p := range r.offers {
+		live = append(live, p)
+	}
+	r.mutex.Unlock()
+
+	for _, p := range live {
+		done, err := w(p)
+		if err != nil {
+			return fmt.Errorf("error walking offer registry: %v", err)
+		}
+		if done {
+			return nil
+		}
+	}
+	return nil
+}
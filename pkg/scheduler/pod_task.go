@@ -1,51 +1,47 @@
 package scheduler
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"code.google.com/p/go-uuid/uuid"
 	"code.google.com/p/goprotobuf/proto"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/fanruiheng/kubernetes-mesos/pkg/scheduler/hostport"
+	"github.com/fanruiheng/kubernetes-mesos/pkg/scheduler/offers"
+	"github.com/fanruiheng/kubernetes-mesos/pkg/scheduler/resource"
 	log "github.com/golang/glog"
 	"github.com/mesos/mesos-go/mesos"
 )
 
-const (
-	containerCpus = 0.25 // initial CPU allocated for executor
-	containerMem  = 64   // initial MB of memory allocated for executor
-)
-
 // A struct that describes a pod task.
 type PodTask struct {
-	ID       string
-	Pod      *api.Pod
-	TaskInfo *mesos.TaskInfo
-	Offer    PerishableOffer
-	launched bool
-	deleted  bool
-	podKey   string
+	ID           string
+	Pod          *api.Pod
+	TaskInfo     *mesos.TaskInfo
+	Offer        offers.PerishableOffer
+	Resources    resource.Config
+	Roles        []string
+	PortMappings []hostport.Mapping
+	State        StateType
+	Flags        map[FlagType]struct{}
+	podKey       string
 }
 
-func rangeResource(name string, ports []uint64) *mesos.Resource {
+func rangeResource(name string, ports []uint64, role string) *mesos.Resource {
 	if len(ports) == 0 {
 		// pod may consist of a container that doesn't expose any ports on the host
 		return nil
 	}
-	return &mesos.Resource{
+	res := &mesos.Resource{
 		Name:   proto.String(name),
 		Type:   mesos.Value_RANGES.Enum(),
 		Ranges: NewRanges(ports),
 	}
-}
-
-// func NewRange(begin uint64, end uint64) *mesos.Value_Ranges {
-func NewRanges(ports []uint64) *mesos.Value_Ranges {
-	r := make([]*mesos.Value_Range, 0)
-	for _, port := range ports {
-		x := proto.Uint64(port)
-		r = append(r, &mesos.Value_Range{Begin: x, End: x})
+	if role != defaultRole {
+		res.Role = proto.String(role)
 	}
-	return &mesos.Value_Ranges{Range: r}
+	return res
 }
 
 func (t *PodTask) hasAcceptedOffer() bool {
@@ -61,7 +57,7 @@ func (t *PodTask) GetOfferId() string {
 
 // Fill the TaskInfo in the PodTask, should be called during k8s scheduling,
 // before binding.
-func (t *PodTask) FillTaskInfo(offer PerishableOffer) error {
+func (t *PodTask) FillTaskInfo(offer offers.PerishableOffer) error {
 	if offer == nil || offer.Details() == nil {
 		return fmt.Errorf("Nil offer for task %v", t)
 	}
@@ -75,15 +71,37 @@ func (t *PodTask) FillTaskInfo(offer PerishableOffer) error {
 	t.Offer = offer
 	log.V(3).Infof("Recording offer(s) %v against pod %v", details.Id, t.Pod.Name)
 
+	// t.PortMappings was computed against this same offer by AcceptOffer's
+	// predicates; only now, with the offer actually bound, is it safe to
+	// commit any wildcard-assigned host ports into the pod spec.
+	hostport.Apply(t.Pod, t.PortMappings)
+
+	resource.ApplyDefaults(t.Pod, t.Resources)
+	cpus := resource.PodCPULimit(t.Pod, t.Resources)
+	mem := resource.PodMemLimit(t.Pod, t.Resources)
+	role := primaryRole(t.Roles)
+
+	cpuResource := mesos.ScalarResource("cpus", float64(cpus))
+	memResource := mesos.ScalarResource("mem", float64(mem))
+	if role != defaultRole {
+		cpuResource.Role = proto.String(role)
+		memResource.Role = proto.String(role)
+	}
+
 	t.TaskInfo.TaskId = &mesos.TaskID{Value: proto.String(t.ID)}
 	t.TaskInfo.SlaveId = details.GetSlaveId()
-	t.TaskInfo.Resources = []*mesos.Resource{
-		mesos.ScalarResource("cpus", containerCpus),
-		mesos.ScalarResource("mem", containerMem),
-	}
-	if ports := rangeResource("ports", t.Ports()); ports != nil {
+	t.TaskInfo.Resources = []*mesos.Resource{cpuResource, memResource}
+	if ports := rangeResource("ports", t.Ports(), role); ports != nil {
 		t.TaskInfo.Resources = append(t.TaskInfo.Resources, ports)
 	}
+
+	if len(t.PortMappings) > 0 {
+		data, err := json.Marshal(t.PortMappings)
+		if err != nil {
+			return fmt.Errorf("failed to encode port mappings for task %v: %v", t, err)
+		}
+		t.TaskInfo.Data = data
+	}
 	return nil
 }
 
@@ -115,52 +133,17 @@ func (t *PodTask) Ports() []uint64 {
 	return ports
 }
 
+// AcceptOffer reports whether offer is viable for t, by running it through
+// DefaultPredicates. t has no SlaveIndex of its own, so every predicate sees
+// a nil SlaveIndex here, including when FCFSScheduleFunc calls AcceptOffer.
+// None of DefaultPredicates consults the SlaveIndex, so host port conflicts
+// with other pods already bound to the same slave are not checked.
 func (t *PodTask) AcceptOffer(offer *mesos.Offer) bool {
-	var cpus float64 = 0
-	var mem float64 = 0
-
-	// Mimic set type
-	requiredPorts := make(map[uint64]struct{})
-	for _, port := range t.Ports() {
-		requiredPorts[port] = struct{}{}
-	}
-
-	for _, resource := range offer.Resources {
-		if resource.GetName() == "cpus" {
-			cpus = *resource.GetScalar().Value
-		}
-
-		if resource.GetName() == "mem" {
-			mem = *resource.GetScalar().Value
+	for _, fits := range DefaultPredicates {
+		if !fits(t, offer, nil) {
+			return false
 		}
-
-		if resource.GetName() == "ports" {
-			for _, r := range (*resource).GetRanges().Range {
-				bp := r.GetBegin()
-				ep := r.GetEnd()
-
-				for port, _ := range requiredPorts {
-					log.V(2).Infof("Evaluating port range {%d:%d} %d", bp, ep, port)
-
-					if (bp <= port) && (port <= ep) {
-						delete(requiredPorts, port)
-					}
-				}
-			}
-		}
-	}
-
-	unsatisfiedPorts := len(requiredPorts)
-	if unsatisfiedPorts > 0 {
-		log.V(2).Infof("Could not schedule pod %s: %d ports could not be allocated", t.Pod.Name, unsatisfiedPorts)
-		return false
 	}
-
-	if (cpus < containerCpus) || (mem < containerMem) {
-		log.V(2).Infof("Not enough resources: cpus: %f mem: %f", cpus, mem)
-		return false
-	}
-
 	return true
 }
 
@@ -171,10 +154,14 @@ func newPodTask(ctx api.Context, pod *api.Pod, executor *mesos.ExecutorInfo) (*P
 	}
 	taskId := uuid.NewUUID().String()
 	task := &PodTask{
-		ID:       taskId,
-		Pod:      pod,
-		TaskInfo: new(mesos.TaskInfo),
-		podKey:   key,
+		ID:        taskId,
+		Pod:       pod,
+		TaskInfo:  new(mesos.TaskInfo),
+		Resources: resource.DefaultConfig,
+		Roles:     rolesFor(pod),
+		State:     StatePending,
+		Flags:     make(map[FlagType]struct{}),
+		podKey:    key,
 	}
 	task.TaskInfo.Name = proto.String("PodTask")
 	task.TaskInfo.Executor = executor
@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"github.com/mesos/mesos-go/mesos"
+)
+
+// StateType captures where a PodTask is in its lifecycle, replacing a set of
+// ad-hoc booleans that couldn't represent states like "we don't actually
+// know what happened to this task" after a scheduler failover.
+type StateType int
+
+const (
+	StatePending StateType = iota
+	StateRunning
+	StateFinished
+	StateUnknown
+)
+
+func (s StateType) String() string {
+	switch s {
+	case StatePending:
+		return "Pending"
+	case StateRunning:
+		return "Running"
+	case StateFinished:
+		return "Finished"
+	case StateUnknown:
+		return "Unknown"
+	default:
+		return "Illegal"
+	}
+}
+
+// FlagType represents a boolean fact about a PodTask that isn't itself part
+// of the Pending/Running/Finished/Unknown lifecycle (e.g. whether it's been
+// launched with Mesos, or bound in the k8s registry).
+type FlagType string
+
+const (
+	Launched FlagType = "launched"
+	Bound    FlagType = "bound"
+	Deleted  FlagType = "deleted"
+)
+
+// Has returns true if the given flag is set on the task.
+func (t *PodTask) Has(f FlagType) bool {
+	_, ok := t.Flags[f]
+	return ok
+}
+
+// Set marks the given flag on the task.
+func (t *PodTask) Set(f FlagType) {
+	if t.Flags == nil {
+		t.Flags = make(map[FlagType]struct{})
+	}
+	t.Flags[f] = struct{}{}
+}
+
+// Clear unmarks the given flag on the task.
+func (t *PodTask) Clear(f FlagType) {
+	delete(t.Flags, f)
+}
+
+// UpdateStatus applies a Mesos task status update to the task's state
+// machine and returns the (previous, current) StateType so that callers can
+// react to the transition. It centralizes the legal Pending -> Running ->
+// Finished transitions, plus the Unknown state a task enters on TASK_LOST:
+// this package doesn't itself track whether we're connected to the master,
+// so every TASK_LOST is treated conservatively as "can't yet tell whether
+// this task is truly gone" rather than assumed Finished. A task in Unknown
+// is resolved the moment any later status update arrives for it (including
+// one a caller obtained via explicit Mesos reconciliation); see
+// Scheduler.UnknownTasks for the entry point a caller uses to drive that.
+func (t *PodTask) UpdateStatus(status *mesos.TaskStatus) (from StateType, to StateType) {
+	from = t.State
+
+	switch status.GetState() {
+	case mesos.TaskState_TASK_STAGING, mesos.TaskState_TASK_STARTING:
+		t.State = StatePending
+
+	case mesos.TaskState_TASK_RUNNING:
+		t.State = StateRunning
+
+	case mesos.TaskState_TASK_FINISHED, mesos.TaskState_TASK_FAILED,
+		mesos.TaskState_TASK_KILLED, mesos.TaskState_TASK_ERROR:
+		t.State = StateFinished
+
+	case mesos.TaskState_TASK_LOST:
+		// we can't yet tell whether this task is truly gone or whether we
+		// simply lost touch with its slave/executor; reconciliation is
+		// responsible for resolving Unknown one way or the other.
+		t.State = StateUnknown
+	}
+
+	return from, t.State
+}
@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/mesos/mesos-go/mesos"
+)
+
+func statusOf(state mesos.TaskState) *mesos.TaskStatus {
+	return &mesos.TaskStatus{State: state.Enum()}
+}
+
+func TestPodTaskUpdateStatusRunning(t *testing.T) {
+	task := &PodTask{State: StatePending}
+	from, to := task.UpdateStatus(statusOf(mesos.TaskState_TASK_RUNNING))
+	if from != StatePending || to != StateRunning {
+		t.Errorf("UpdateStatus(TASK_RUNNING) = (%v, %v), want (%v, %v)", from, to, StatePending, StateRunning)
+	}
+	if task.State != StateRunning {
+		t.Errorf("task.State = %v, want %v", task.State, StateRunning)
+	}
+}
+
+func TestPodTaskUpdateStatusFinished(t *testing.T) {
+	task := &PodTask{State: StateRunning}
+	from, to := task.UpdateStatus(statusOf(mesos.TaskState_TASK_FINISHED))
+	if from != StateRunning || to != StateFinished {
+		t.Errorf("UpdateStatus(TASK_FINISHED) = (%v, %v), want (%v, %v)", from, to, StateRunning, StateFinished)
+	}
+}
+
+func TestPodTaskUpdateStatusLostGoesUnknown(t *testing.T) {
+	task := &PodTask{State: StateRunning}
+	_, to := task.UpdateStatus(statusOf(mesos.TaskState_TASK_LOST))
+	if to != StateUnknown {
+		t.Errorf("UpdateStatus(TASK_LOST) = %v, want %v", to, StateUnknown)
+	}
+}
+
+func TestPodTaskFlags(t *testing.T) {
+	task := &PodTask{}
+	if task.Has(Launched) {
+		t.Error("expected a fresh task to not have Launched set")
+	}
+	task.Set(Launched)
+	if !task.Has(Launched) {
+		t.Error("expected Launched to be set after Set(Launched)")
+	}
+	task.Clear(Launched)
+	if task.Has(Launched) {
+		t.Error("expected Launched to be cleared after Clear(Launched)")
+	}
+}
@@ -0,0 +1,64 @@
+package scheduler
+
+import (
+	"sort"
+
+	"code.google.com/p/goprotobuf/proto"
+	"github.com/mesos/mesos-go/mesos"
+)
+
+// NewRanges converts ports into the minimal set of contiguous
+// mesos.Value_Ranges that cover it.
+func NewRanges(ports []uint64) *mesos.Value_Ranges {
+	return &mesos.Value_Ranges{Range: ArrayToRanges(ports)}
+}
+
+// ArrayToRanges sorts, dedupes, and collapses runs of consecutive ports into
+// the minimal set of [begin,end] mesos.Value_Range entries that cover them.
+func ArrayToRanges(ports []uint64) []*mesos.Value_Range {
+	if len(ports) == 0 {
+		return nil
+	}
+
+	sorted := make(uint64Slice, len(ports))
+	copy(sorted, ports)
+	sort.Sort(sorted)
+
+	ranges := make([]*mesos.Value_Range, 0, len(sorted))
+	begin, end := sorted[0], sorted[0]
+	for _, port := range sorted[1:] {
+		switch {
+		case port == end:
+			// duplicate of the port we're already covering
+		case port == end+1:
+			end = port
+		default:
+			ranges = append(ranges, valueRange(begin, end))
+			begin, end = port, port
+		}
+	}
+	return append(ranges, valueRange(begin, end))
+}
+
+// RangesToArray is the inverse of ArrayToRanges: it expands a set of
+// mesos.Value_Range entries back into the sorted list of individual ports
+// they cover.
+func RangesToArray(ranges []*mesos.Value_Range) []uint64 {
+	var ports []uint64
+	for _, r := range ranges {
+		for port := r.GetBegin(); port <= r.GetEnd(); port++ {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+func valueRange(begin, end uint64) *mesos.Value_Range {
+	return &mesos.Value_Range{Begin: proto.Uint64(begin), End: proto.Uint64(end)}
+}
+
+type uint64Slice []uint64
+
+func (s uint64Slice) Len() int           { return len(s) }
+func (s uint64Slice) Less(i, j int) bool { return s[i] < s[j] }
+func (s uint64Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
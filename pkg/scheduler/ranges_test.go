@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mesos/mesos-go/mesos"
+)
+
+func rangeOf(begin, end uint64) *mesos.Value_Range {
+	return valueRange(begin, end)
+}
+
+func TestArrayToRangesEmpty(t *testing.T) {
+	if r := ArrayToRanges(nil); r != nil {
+		t.Errorf("expected nil ranges for empty input, got %v", r)
+	}
+}
+
+func TestArrayToRangesSinglePort(t *testing.T) {
+	got := ArrayToRanges([]uint64{8080})
+	want := []*mesos.Value_Range{rangeOf(8080, 8080)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ArrayToRanges(8080) = %v, want %v", got, want)
+	}
+}
+
+func TestArrayToRangesAllContiguous(t *testing.T) {
+	got := ArrayToRanges([]uint64{8000, 8001, 8002, 8003})
+	want := []*mesos.Value_Range{rangeOf(8000, 8003)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ArrayToRanges(contiguous) = %v, want %v", got, want)
+	}
+}
+
+func TestArrayToRangesAllDisjoint(t *testing.T) {
+	got := ArrayToRanges([]uint64{8000, 8080, 9000})
+	want := []*mesos.Value_Range{
+		rangeOf(8000, 8000),
+		rangeOf(8080, 8080),
+		rangeOf(9000, 9000),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ArrayToRanges(disjoint) = %v, want %v", got, want)
+	}
+}
+
+func TestArrayToRangesDuplicatesAndUnsorted(t *testing.T) {
+	got := ArrayToRanges([]uint64{8002, 8000, 8001, 8001, 9000})
+	want := []*mesos.Value_Range{
+		rangeOf(8000, 8002),
+		rangeOf(9000, 9000),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ArrayToRanges(dup/unsorted) = %v, want %v", got, want)
+	}
+}
+
+func TestRangesToArrayRoundTrip(t *testing.T) {
+	ports := []uint64{8000, 8001, 8002, 8080, 9000, 9001}
+	ranges := ArrayToRanges(ports)
+	got := RangesToArray(ranges)
+	if !reflect.DeepEqual(got, ports) {
+		t.Errorf("RangesToArray(ArrayToRanges(ports)) = %v, want %v", got, ports)
+	}
+}
@@ -0,0 +1,169 @@
+// Package resource computes the Mesos cpu/mem resources required to run a
+// pod's containers, based on the resource requests/limits the pod spec
+// already carries.
+package resource
+
+import (
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
+)
+
+// CPUShares is the unit Mesos uses to describe fractional cpu allocations,
+// e.g. 0.25 for a quarter of a cpu.
+type CPUShares float64
+
+// MegaBytes is the unit Mesos uses to describe memory allocations.
+type MegaBytes float64
+
+const (
+	// DefaultContainerCPULimit is applied to a container that declares
+	// neither a cpu request nor a cpu limit.
+	DefaultContainerCPULimit CPUShares = 0.25
+
+	// DefaultContainerMemLimit is applied to a container that declares
+	// neither a memory request nor a memory limit.
+	DefaultContainerMemLimit MegaBytes = 64
+
+	// DefaultExecutorCPU is reserved, on top of the sum of a pod's container
+	// requirements, to run the per-pod executor that mesos-kubernetes
+	// launches alongside each task.
+	DefaultExecutorCPU CPUShares = 0.1
+
+	// DefaultExecutorMem is reserved, on top of the sum of a pod's container
+	// requirements, to run the per-pod executor that mesos-kubernetes
+	// launches alongside each task.
+	DefaultExecutorMem MegaBytes = 32
+)
+
+// DefaultConfig is the Config used when the scheduler isn't given a more
+// specific one.
+var DefaultConfig = Config{
+	DefaultCPULimit:   DefaultContainerCPULimit,
+	DefaultMemLimit:   DefaultContainerMemLimit,
+	DefaultCPURequest: DefaultContainerCPULimit,
+	DefaultMemRequest: DefaultContainerMemLimit,
+	ExecutorCPU:       DefaultExecutorCPU,
+	ExecutorMem:       DefaultExecutorMem,
+}
+
+// Config controls how container cpu/mem requests and limits are defaulted
+// and summed when computing the Mesos resources required for a pod, and how
+// much extra is set aside for the per-pod executor.
+type Config struct {
+	DefaultCPULimit   CPUShares
+	DefaultMemLimit   MegaBytes
+	DefaultCPURequest CPUShares
+	DefaultMemRequest MegaBytes
+	ExecutorCPU       CPUShares
+	ExecutorMem       MegaBytes
+}
+
+// ApplyDefaults fills in any missing cpu/mem request or limit on pod's
+// containers using cfg, so that later reads of Resources.Requests/Limits
+// never have to special-case "unset". It's idempotent: containers that
+// already specify a value are left alone. A limit that's left to default is
+// floored at the container's (possibly just-defaulted) request, so that a
+// container which only specifies a request never ends up with a lower
+// limit than what it asked for.
+func ApplyDefaults(pod *api.Pod, cfg Config) {
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		if c.Resources.Limits == nil {
+			c.Resources.Limits = api.ResourceList{}
+		}
+		if c.Resources.Requests == nil {
+			c.Resources.Requests = api.ResourceList{}
+		}
+
+		defaultQuantity(c.Resources.Requests, api.ResourceCPU, float64(cfg.DefaultCPURequest))
+		defaultQuantity(c.Resources.Requests, api.ResourceMemory, float64(cfg.DefaultMemRequest)*1024*1024)
+
+		defaultQuantity(c.Resources.Limits, api.ResourceCPU, floorAt(float64(cfg.DefaultCPULimit), c.Resources.Requests, api.ResourceCPU))
+		defaultQuantity(c.Resources.Limits, api.ResourceMemory, floorAt(float64(cfg.DefaultMemLimit)*1024*1024, c.Resources.Requests, api.ResourceMemory))
+	}
+}
+
+// floorAt returns the larger of def and rl[name]'s quantity value (0 if
+// name isn't present in rl).
+func floorAt(def float64, rl api.ResourceList, name api.ResourceName) float64 {
+	q, ok := rl[name]
+	if !ok {
+		return def
+	}
+	var value float64
+	if name == api.ResourceCPU {
+		value = float64(q.MilliValue()) / 1000.0
+	} else {
+		value = float64(q.Value())
+	}
+	if value > def {
+		return value
+	}
+	return def
+}
+
+func defaultQuantity(rl api.ResourceList, name api.ResourceName, value float64) {
+	if _, ok := rl[name]; ok {
+		return
+	}
+	if name == api.ResourceCPU {
+		rl[name] = *resource.NewMilliQuantity(int64(value*1000), resource.DecimalSI)
+		return
+	}
+	rl[name] = *resource.NewQuantity(int64(value), resource.BinarySI)
+}
+
+// PodCPULimit returns the total cpu shares of pod, including the executor
+// overhead, summing each container's cpu limit and falling back to
+// cfg.DefaultCPULimit for any container that doesn't specify one.
+func PodCPULimit(pod *api.Pod, cfg Config) CPUShares {
+	return sumCPU(pod, cfg.DefaultCPULimit, limits) + cfg.ExecutorCPU
+}
+
+// PodCPURequest returns the total cpu shares of pod, including the executor
+// overhead, summing each container's cpu request and falling back to
+// cfg.DefaultCPURequest for any container that doesn't specify one.
+func PodCPURequest(pod *api.Pod, cfg Config) CPUShares {
+	return sumCPU(pod, cfg.DefaultCPURequest, requests) + cfg.ExecutorCPU
+}
+
+// PodMemLimit returns the total memory of pod, including the executor
+// overhead, summing each container's memory limit and falling back to
+// cfg.DefaultMemLimit for any container that doesn't specify one.
+func PodMemLimit(pod *api.Pod, cfg Config) MegaBytes {
+	return sumMem(pod, cfg.DefaultMemLimit, limits) + cfg.ExecutorMem
+}
+
+// PodMemRequest returns the total memory of pod, including the executor
+// overhead, summing each container's memory request and falling back to
+// cfg.DefaultMemRequest for any container that doesn't specify one.
+func PodMemRequest(pod *api.Pod, cfg Config) MegaBytes {
+	return sumMem(pod, cfg.DefaultMemRequest, requests) + cfg.ExecutorMem
+}
+
+func limits(r api.ResourceRequirements) api.ResourceList   { return r.Limits }
+func requests(r api.ResourceRequirements) api.ResourceList { return r.Requests }
+
+func sumCPU(pod *api.Pod, def CPUShares, list func(api.ResourceRequirements) api.ResourceList) CPUShares {
+	var total CPUShares
+	for _, c := range pod.Spec.Containers {
+		if q, ok := list(c.Resources)[api.ResourceCPU]; ok {
+			total += CPUShares(float64(q.MilliValue()) / 1000.0)
+		} else {
+			total += def
+		}
+	}
+	return total
+}
+
+func sumMem(pod *api.Pod, def MegaBytes, list func(api.ResourceRequirements) api.ResourceList) MegaBytes {
+	var total MegaBytes
+	for _, c := range pod.Spec.Containers {
+		if q, ok := list(c.Resources)[api.ResourceMemory]; ok {
+			total += MegaBytes(float64(q.Value()) / (1024 * 1024))
+		} else {
+			total += def
+		}
+	}
+	return total
+}
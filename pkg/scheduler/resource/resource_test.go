@@ -0,0 +1,88 @@
+package resource
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
+)
+
+func podWithContainer(r api.ResourceRequirements) *api.Pod {
+	return &api.Pod{
+		Spec: api.PodSpec{
+			Containers: []api.Container{
+				{Resources: r},
+			},
+		},
+	}
+}
+
+func TestApplyDefaultsUnsetContainer(t *testing.T) {
+	pod := podWithContainer(api.ResourceRequirements{})
+	ApplyDefaults(pod, DefaultConfig)
+
+	c := pod.Spec.Containers[0]
+	if got := CPUShares(c.Resources.Limits[api.ResourceCPU].MilliValue()) / 1000; got != DefaultContainerCPULimit {
+		t.Errorf("Limits.cpu = %v, want %v", got, DefaultContainerCPULimit)
+	}
+	if got := CPUShares(c.Resources.Requests[api.ResourceCPU].MilliValue()) / 1000; got != DefaultContainerCPULimit {
+		t.Errorf("Requests.cpu = %v, want %v", got, DefaultContainerCPULimit)
+	}
+}
+
+func TestApplyDefaultsRequestOnlyFloorsLimit(t *testing.T) {
+	// A container that only declares a cpu/mem request above the default
+	// limit must not end up with a Limit lower than what it asked for.
+	pod := podWithContainer(api.ResourceRequirements{
+		Requests: api.ResourceList{
+			api.ResourceCPU:    *resource.NewMilliQuantity(2000, resource.DecimalSI),
+			api.ResourceMemory: *resource.NewQuantity(256*1024*1024, resource.BinarySI),
+		},
+	})
+	ApplyDefaults(pod, DefaultConfig)
+
+	c := pod.Spec.Containers[0]
+	if got := c.Resources.Limits[api.ResourceCPU].MilliValue(); got != 2000 {
+		t.Errorf("Limits.cpu = %d milli, want 2000 (floored at request)", got)
+	}
+	if got := c.Resources.Limits[api.ResourceMemory].Value(); got != 256*1024*1024 {
+		t.Errorf("Limits.memory = %d, want %d (floored at request)", got, 256*1024*1024)
+	}
+}
+
+func TestApplyDefaultsLeavesExplicitLimitAlone(t *testing.T) {
+	pod := podWithContainer(api.ResourceRequirements{
+		Limits: api.ResourceList{
+			api.ResourceCPU: *resource.NewMilliQuantity(500, resource.DecimalSI),
+		},
+	})
+	ApplyDefaults(pod, DefaultConfig)
+
+	if got := pod.Spec.Containers[0].Resources.Limits[api.ResourceCPU].MilliValue(); got != 500 {
+		t.Errorf("Limits.cpu = %d milli, want 500 (explicit value left untouched)", got)
+	}
+}
+
+func TestPodCPULimitMatchesPodCPURequestWhenUnset(t *testing.T) {
+	pod := podWithContainer(api.ResourceRequirements{})
+	ApplyDefaults(pod, DefaultConfig)
+
+	limit := PodCPULimit(pod, DefaultConfig)
+	request := PodCPURequest(pod, DefaultConfig)
+	if limit != request {
+		t.Errorf("PodCPULimit = %v, PodCPURequest = %v, want equal once defaulted", limit, request)
+	}
+}
+
+func TestPodCPULimitNeverBelowRequest(t *testing.T) {
+	pod := podWithContainer(api.ResourceRequirements{
+		Requests: api.ResourceList{
+			api.ResourceCPU: *resource.NewMilliQuantity(2000, resource.DecimalSI),
+		},
+	})
+	ApplyDefaults(pod, DefaultConfig)
+
+	if limit, request := PodCPULimit(pod, DefaultConfig), PodCPURequest(pod, DefaultConfig); limit < request {
+		t.Errorf("PodCPULimit = %v is below PodCPURequest = %v", limit, request)
+	}
+}
@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/mesos/mesos-go/mesos"
+)
+
+// RolesAnnotationKey, when set on a pod, overrides FrameworkRoles for that
+// pod's task with a comma-separated list of Mesos roles, e.g.
+// "k8s.mesosphere.io/roles: public,*".
+const RolesAnnotationKey = "k8s.mesosphere.io/roles"
+
+// FrameworkRoles lists the Mesos roles this scheduler's framework is
+// registered under and may therefore consume resources from. "*" is the
+// role every unreserved resource belongs to, so accepting it is the only
+// sane default.
+var FrameworkRoles = []string{"*"}
+
+// defaultRole is what an offer Resource's Role is taken to be when Mesos
+// leaves the (optional) field unset.
+const defaultRole = "*"
+
+// resourceRole returns res's role, defaulting to "*" when unset.
+func resourceRole(res *mesos.Resource) string {
+	if r := res.GetRole(); r != "" {
+		return r
+	}
+	return defaultRole
+}
+
+// hasRole reports whether role is among roles.
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// rolesFor returns the Mesos roles pod's task may draw resources from: the
+// comma-separated list in the pod's RolesAnnotationKey annotation if
+// present, otherwise FrameworkRoles.
+func rolesFor(pod *api.Pod) []string {
+	v, ok := pod.Annotations[RolesAnnotationKey]
+	if !ok || v == "" {
+		return FrameworkRoles
+	}
+	parts := strings.Split(v, ",")
+	roles := make([]string, 0, len(parts))
+	for _, r := range parts {
+		if r = strings.TrimSpace(r); r != "" {
+			roles = append(roles, r)
+		}
+	}
+	return roles
+}
+
+// filterOfferRoles returns a copy of offer whose Resources are restricted to
+// those whose Role is among roles, so that code which isn't itself
+// role-aware (e.g. the hostport package) never draws from a reservation the
+// task isn't allowed to use.
+func filterOfferRoles(offer *mesos.Offer, roles []string) *mesos.Offer {
+	filtered := *offer
+	filtered.Resources = nil
+	for _, res := range offer.Resources {
+		if hasRole(roles, resourceRole(res)) {
+			filtered.Resources = append(filtered.Resources, res)
+		}
+	}
+	return &filtered
+}
+
+// primaryRole picks the role a task's own Mesos resources should be tagged
+// with: the first reserved (non-"*") role the task is allowed to use, or
+// "*" if it isn't restricted to any reservation.
+func primaryRole(roles []string) string {
+	for _, r := range roles {
+		if r != defaultRole {
+			return r
+		}
+	}
+	return defaultRole
+}
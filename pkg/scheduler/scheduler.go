@@ -0,0 +1,129 @@
+package scheduler
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/fanruiheng/kubernetes-mesos/pkg/scheduler/offers"
+	log "github.com/golang/glog"
+	"github.com/mesos/mesos-go/mesos"
+)
+
+// Scheduler tracks the PodTasks currently known to the scheduler, keyed by
+// Mesos task id, so that status updates from Mesos and SlaveIndex lookups
+// during scheduling have somewhere real to land, and matches tasks against
+// Offers using the pluggable Algorithm.
+type Scheduler struct {
+	Algorithm SchedulerAlgorithm
+	Offers    offers.Registry
+
+	mutex sync.Mutex
+	tasks map[string]*PodTask
+}
+
+// NewScheduler returns a Scheduler that matches tasks against the offers in
+// offerRegistry using algorithm, so that callers can plug in bin-packing,
+// spread, or role-aware strategies in place of the default. A nil algorithm
+// falls back to FCFSScheduleFunc.
+func NewScheduler(algorithm SchedulerAlgorithm, offerRegistry offers.Registry) *Scheduler {
+	if algorithm == nil {
+		algorithm = FCFSScheduleFunc
+	}
+	return &Scheduler{
+		Algorithm: algorithm,
+		Offers:    offerRegistry,
+		tasks:     make(map[string]*PodTask),
+	}
+}
+
+// Schedule picks an offer for task via s.Algorithm, fills in task's
+// TaskInfo against it, and registers task so that future StatusUpdate and
+// SlaveIndex lookups can find it. On error the offer, if any was acquired,
+// is released back to the registry.
+func (s *Scheduler) Schedule(task *PodTask) (offers.PerishableOffer, error) {
+	offer, err := s.Algorithm(s.Offers, s, task)
+	if err != nil {
+		return nil, err
+	}
+	if err := task.FillTaskInfo(offer); err != nil {
+		offer.Release()
+		return nil, err
+	}
+	s.Register(task)
+	return offer, nil
+}
+
+// Register starts tracking task under its ID.
+func (s *Scheduler) Register(task *PodTask) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.tasks[task.ID] = task
+}
+
+// Forget stops tracking the task with the given id.
+func (s *Scheduler) Forget(taskId string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.tasks, taskId)
+}
+
+// Tasks implements SlaveIndex, returning the tracked tasks currently bound
+// to slaveId.
+func (s *Scheduler) Tasks(slaveId string) []*PodTask {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var bound []*PodTask
+	for _, t := range s.tasks {
+		if t.TaskInfo.GetSlaveId().GetValue() == slaveId {
+			bound = append(bound, t)
+		}
+	}
+	return bound
+}
+
+// StatusUpdate applies a Mesos task status update to the tracked task it
+// names, driving it through PodTask's state machine, and returns the
+// (previous, current) StateType. A task that finishes is unregistered, since
+// a Finished task never transitions again.
+func (s *Scheduler) StatusUpdate(status *mesos.TaskStatus) (from StateType, to StateType, err error) {
+	taskId := status.GetTaskId().GetValue()
+
+	s.mutex.Lock()
+	task, ok := s.tasks[taskId]
+	s.mutex.Unlock()
+	if !ok {
+		return StateUnknown, StateUnknown, fmt.Errorf("status update for unknown task %s", taskId)
+	}
+
+	from, to = task.UpdateStatus(status)
+	switch to {
+	case StateRunning:
+		task.Set(Launched)
+	case StateFinished:
+		task.Clear(Launched)
+		s.Forget(taskId)
+	}
+	log.V(2).Infof("task %s status update: %s -> %s", taskId, from, to)
+	return from, to, nil
+}
+
+// UnknownTasks returns the tracked tasks currently in StateUnknown -- those
+// whose fate became uncertain after a TASK_LOST arrived. It's the entry
+// point explicit reconciliation is built on: a caller wiring up a
+// mesos.SchedulerDriver calls this to get the tasks to ask Mesos about,
+// builds the corresponding []*mesos.TaskStatus, and passes it to
+// SchedulerDriver.ReconcileTasks; whatever status updates come back are fed
+// through StatusUpdate like any other, resolving Unknown to Running or
+// Finished. This package doesn't hold a SchedulerDriver itself, so driving
+// that call is left to the caller.
+func (s *Scheduler) UnknownTasks() []*PodTask {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var unknown []*PodTask
+	for _, t := range s.tasks {
+		if t.State == StateUnknown {
+			unknown = append(unknown, t)
+		}
+	}
+	return unknown
+}
@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"testing"
+
+	"code.google.com/p/goprotobuf/proto"
+	"github.com/mesos/mesos-go/mesos"
+)
+
+func TestSchedulerStatusUpdateDrivesTaskState(t *testing.T) {
+	s := NewScheduler(nil, nil)
+	task := &PodTask{ID: "task1", TaskInfo: new(mesos.TaskInfo), State: StatePending}
+	s.Register(task)
+
+	status := &mesos.TaskStatus{
+		TaskId: &mesos.TaskID{Value: proto.String("task1")},
+		State:  mesos.TaskState_TASK_RUNNING.Enum(),
+	}
+	from, to, err := s.StatusUpdate(status)
+	if err != nil {
+		t.Fatalf("StatusUpdate returned error: %v", err)
+	}
+	if from != StatePending || to != StateRunning {
+		t.Errorf("StatusUpdate = (%v, %v), want (%v, %v)", from, to, StatePending, StateRunning)
+	}
+	if !task.Has(Launched) {
+		t.Error("expected task to be marked Launched once Running")
+	}
+}
+
+func TestSchedulerStatusUpdateForgetsFinishedTask(t *testing.T) {
+	s := NewScheduler(nil, nil)
+	task := &PodTask{ID: "task1", TaskInfo: new(mesos.TaskInfo), State: StateRunning}
+	task.Set(Launched)
+	s.Register(task)
+
+	status := &mesos.TaskStatus{
+		TaskId: &mesos.TaskID{Value: proto.String("task1")},
+		State:  mesos.TaskState_TASK_FINISHED.Enum(),
+	}
+	if _, _, err := s.StatusUpdate(status); err != nil {
+		t.Fatalf("StatusUpdate returned error: %v", err)
+	}
+	if task.Has(Launched) {
+		t.Error("expected Launched to be cleared once Finished")
+	}
+	if len(s.Tasks("")) != 0 {
+		t.Error("expected the task to be forgotten once Finished")
+	}
+}
+
+func TestSchedulerUnknownTasks(t *testing.T) {
+	s := NewScheduler(nil, nil)
+	lost := &PodTask{ID: "task1", TaskInfo: new(mesos.TaskInfo), State: StateRunning}
+	s.Register(lost)
+	running := &PodTask{ID: "task2", TaskInfo: new(mesos.TaskInfo), State: StateRunning}
+	s.Register(running)
+
+	if _, _, err := s.StatusUpdate(&mesos.TaskStatus{
+		TaskId: &mesos.TaskID{Value: proto.String("task1")},
+		State:  mesos.TaskState_TASK_LOST.Enum(),
+	}); err != nil {
+		t.Fatalf("StatusUpdate returned error: %v", err)
+	}
+
+	unknown := s.UnknownTasks()
+	if len(unknown) != 1 || unknown[0].ID != "task1" {
+		t.Errorf("UnknownTasks() = %v, want just task1", unknown)
+	}
+
+	// A later status update for the lost task resolves it out of Unknown,
+	// simulating the response to explicit reconciliation.
+	if _, to, err := s.StatusUpdate(&mesos.TaskStatus{
+		TaskId: &mesos.TaskID{Value: proto.String("task1")},
+		State:  mesos.TaskState_TASK_RUNNING.Enum(),
+	}); err != nil || to != StateRunning {
+		t.Fatalf("reconciling StatusUpdate = (_, %v, %v), want (_, %v, nil)", to, err, StateRunning)
+	}
+	if unknown := s.UnknownTasks(); len(unknown) != 0 {
+		t.Errorf("UnknownTasks() = %v, want none once task1 is resolved", unknown)
+	}
+}
+
+func TestSchedulerStatusUpdateUnknownTask(t *testing.T) {
+	s := NewScheduler(nil, nil)
+	status := &mesos.TaskStatus{
+		TaskId: &mesos.TaskID{Value: proto.String("missing")},
+		State:  mesos.TaskState_TASK_RUNNING.Enum(),
+	}
+	if _, _, err := s.StatusUpdate(status); err == nil {
+		t.Error("expected an error for a status update naming an untracked task")
+	}
+}